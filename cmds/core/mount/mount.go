@@ -8,21 +8,46 @@
 //
 // Synopsis:
 //     mount [-r] [-o options] [-t FSTYPE] DEV PATH
+//     mount -a [-T FSTAB] [-t FSTYPE] [-O OPTS] [-F]
+//     mount [-m PREFIX] [-s SOURCE] [-t FSTYPE] [-p PROPAGATION] [-json]
+//     mount --make-[r]shared|slave|private|unbindable PATH
+//     mount --move OLD NEW
+//     mount --remount [-o opts] [-r] PATH
+//     mount -N [--keep-alive] ... [--exec CMD [ARGS...]]
 //
 // Options:
 //     -r: read only
+//     -a: mount all filesystems in FSTAB (default /etc/fstab) not already mounted
+//     -T: fstab file to read with -a (default /etc/fstab)
+//     -O: with -a, only mount entries that have all of these comma separated options
+//     -F: with -a, stop at the first entry that fails to mount (without -F, keep going but exit nonzero if any entry failed)
+//
+// With no DEV PATH arguments and without -a, mount lists the current mount
+// table (from /proc/self/mountinfo), optionally filtered by mount point
+// prefix (-m), source (-s), filesystem type (-t), or propagation type (-p,
+// one of shared, slave, private, unbindable), and optionally as JSON
+// (-json).
+//
+// -N (or --unshare) runs the requested mount in a new, private mount
+// namespace, so it does not affect the host. Combine it with --exec CMD
+// [ARGS...] to exec CMD once the mount is done, or with --keep-alive to
+// block afterwards so another process can nsenter(1) the namespace.
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/u-root/u-root/pkg/mount"
+	"github.com/u-root/u-root/pkg/mount/fstab"
 	"github.com/u-root/u-root/pkg/mount/loop"
+	"github.com/u-root/u-root/pkg/mount/mountinfo"
 	"golang.org/x/sys/unix"
 )
 
@@ -41,10 +66,19 @@ func (o *mountOptions) Set(value string) error {
 
 var (
 	ro     = flag.Bool("r", false, "Read only mount")
-	fsType = flag.String("t", "", "File system type")
+	fsType = flag.String("t", "", "File system type. With -a, a comma separated list, optionally prefixed with \"no\" to exclude rather than include")
 	bind   = flag.Bool("bind", false, "Mount with -o bind")
 	rbind  = flag.Bool("rbind", false, "Mount with -o bind,rec")
 
+	all       = flag.Bool("a", false, "Mount all filesystems in fstab not already mounted")
+	fstabFile = flag.String("T", "/etc/fstab", "fstab file to read with -a")
+	failFast  = flag.Bool("F", false, "With -a, abort on the first entry that fails to mount")
+
+	listMountpoint = flag.String("m", "", "With no DEV PATH, only list mounts whose mount point has this prefix")
+	listSource     = flag.String("s", "", "With no DEV PATH, only list mounts with this source")
+	propagation    = flag.String("p", "", "With no DEV PATH, only list mounts with this propagation type (shared, slave, private, unbindable)")
+	jsonOut        = flag.Bool("json", false, "With no DEV PATH, emit the listing as JSON")
+
 	makeShared      = flag.Bool("make-shared", false, "Mount with -o shared")
 	makeSlave       = flag.Bool("make-slave", false, "Mount with -o slave")
 	makePrivate     = flag.Bool("make-private", false, "Mount with -o private")
@@ -54,11 +88,16 @@ var (
 	makeRPrivate    = flag.Bool("make-rprivate", false, "Mount with -o private,rec")
 	makeRUnbindable = flag.Bool("make-runbindable", false, "Mount with -o unbindable,rec")
 
-	options mountOptions
+	move    = flag.Bool("move", false, "Move an existing mount: mount --move OLD NEW")
+	remount = flag.Bool("remount", false, "Remount an existing mount with new options: mount --remount [-o opts] [-r] PATH")
+
+	options      mountOptions
+	fstabOptions mountOptions
 )
 
 func init() {
 	flag.Var(&options, "o", "Comma separated list of mount options")
+	flag.Var(&fstabOptions, "O", "With -a, only mount fstab entries that have all of these comma separated options")
 }
 
 func loopSetup(filename string) (loopDevice string, err error) {
@@ -107,16 +146,321 @@ func informIfUnknownFS(originFS string) {
 	}
 }
 
+// fsTypeMatches implements the mount(8) -t semantics: an empty filter
+// matches everything; a filter of "no"-prefixed types excludes those types
+// and matches everything else; otherwise the filter is an allow list.
+func fsTypeMatches(vfsType, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	types := strings.Split(filter, ",")
+	if strings.HasPrefix(types[0], "no") {
+		for _, t := range types {
+			if strings.TrimPrefix(t, "no") == vfsType {
+				return false
+			}
+		}
+		return true
+	}
+	for _, t := range types {
+		if t == vfsType {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPropagation reports whether mi has the given propagation type, per
+// the "optional fields" described in proc(5): "shared:X" entries mean
+// shared, "master:X" entries mean slave, a bare "unbindable" entry means
+// unbindable, and no optional fields at all means private. An empty want
+// matches everything.
+func matchesPropagation(mi mountinfo.MountInfo, want string) bool {
+	if want == "" {
+		return true
+	}
+	switch want {
+	case "private":
+		return len(mi.Optional) == 0
+	case "unbindable":
+		for _, o := range mi.Optional {
+			if o == "unbindable" {
+				return true
+			}
+		}
+		return false
+	case "shared":
+		for _, o := range mi.Optional {
+			if strings.HasPrefix(o, "shared:") {
+				return true
+			}
+		}
+		return false
+	case "slave":
+		for _, o := range mi.Optional {
+			if strings.HasPrefix(o, "master:") {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// hasAllOptions reports whether every option in want is present in have.
+func hasAllOptions(have []string, want []string) bool {
+	for _, w := range want {
+		var found bool
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// mountEntry performs the mount described by a single fstab entry, applying
+// the same loop-device and mount-option handling as the DEV PATH form.
+func mountEntry(e fstab.Entry) error {
+	var flags uintptr
+	var data []string
+	dev := e.Spec
+	for _, option := range e.Options() {
+		switch option {
+		case "loop":
+			var err error
+			dev, err = loopSetup(dev)
+			if err != nil {
+				return fmt.Errorf("error setting loop device: %w", err)
+			}
+		default:
+			if f, ok := opts[option]; ok {
+				flags |= f
+			} else {
+				data = append(data, option)
+			}
+		}
+	}
+	if e.VfsType == "auto" {
+		_, err := mount.TryMount(dev, e.File, strings.Join(data, ","), flags)
+		return err
+	}
+	_, err := mount.Mount(dev, e.File, e.VfsType, strings.Join(data, ","), flags)
+	return err
+}
+
+// pathDepth counts the path components of path, treating "/" as depth 0,
+// so that "/" sorts before "/mnt" and "/mnt" sorts before "/mnt/usb".
+// Counting slashes directly would put "/" and "/mnt" at the same depth.
+func pathDepth(path string) int {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return 0
+	}
+	return len(strings.Split(path, "/"))
+}
+
+// mountAll parses fstabPath and mounts every entry that is not already
+// mounted, filtered by -t and -O, in an order that mounts parents before
+// children. A failure to mount one entry is logged and does not stop the
+// others unless failFast is set.
+func mountAll(fstabPath string) error {
+	entries, err := fstab.ParseFile(fstabPath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", fstabPath, err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return pathDepth(entries[i].File) < pathDepth(entries[j].File)
+	})
+
+	var failed bool
+	for _, e := range entries {
+		if e.HasOption("noauto") {
+			continue
+		}
+		if !fsTypeMatches(e.VfsType, *fsType) {
+			continue
+		}
+		if !hasAllOptions(e.Options(), fstabOptions) {
+			continue
+		}
+		mounted, err := mountinfo.Mounted(e.File)
+		if err != nil {
+			return fmt.Errorf("error checking %s: %w", e.File, err)
+		}
+		if mounted {
+			continue
+		}
+		if err := mountEntry(e); err != nil {
+			log.Printf("mount: %s: %v", e.File, err)
+			if *failFast {
+				return err
+			}
+			failed = true
+		}
+	}
+	if failed {
+		return fmt.Errorf("one or more fstab entries failed to mount")
+	}
+	return nil
+}
+
 func main() {
-	n := []string{"/proc/self/mounts", "/proc/mounts", "/etc/mtab"}
-	for _, p := range n {
-		if b, err := ioutil.ReadFile(p); err == nil {
-			fmt.Print(string(b))
-			os.Exit(0)
+	rest, execCmd := splitExec(os.Args[1:])
+	if err := flag.CommandLine.Parse(rest); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if (*unshare || *unshareLong) && !isUnshareChild() {
+		unshareAndReexec(rest, execCmd)
+		return
+	}
+	if isUnshareChild() {
+		enterPrivateNamespace()
+	}
+
+	if *all {
+		if err := mountAll(*fstabFile); err != nil {
+			log.Fatal(err)
+		}
+		afterMount(execCmd)
+		return
+	}
+
+	if len(flag.Args()) == 0 && len(execCmd) == 0 && !*keepAlive {
+		mounts, err := mountinfo.GetMounts()
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		var filtered []mountinfo.MountInfo
+		for _, m := range mounts {
+			if *listMountpoint != "" && !strings.HasPrefix(m.Mountpoint, *listMountpoint) {
+				continue
+			}
+			if *listSource != "" && m.Source != *listSource {
+				continue
+			}
+			if !fsTypeMatches(m.FSType, *fsType) {
+				continue
+			}
+			if !matchesPropagation(m, *propagation) {
+				continue
+			}
+			filtered = append(filtered, m)
+		}
+		if *jsonOut {
+			b, err := json.MarshalIndent(filtered, "", "  ")
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+			fmt.Println(string(b))
+		} else {
+			for _, m := range filtered {
+				fmt.Printf("%s on %s type %s (%s)\n", m.Source, m.Mountpoint, m.FSType, strings.Join(m.VFSOptions, ","))
+			}
+		}
+		os.Exit(0)
+	}
+
+	if len(flag.Args()) == 0 {
+		// No DEV PATH/OLD NEW/PATH was given, but -N was combined with
+		// --exec or --keep-alive: there is no mount to perform, just the
+		// namespace to act in.
+		afterMount(execCmd)
+		return
+	}
+
+	isPropagation := *makeShared || *makeRShared || *makeSlave || *makeRSlave ||
+		*makePrivate || *makeRPrivate || *makeUnbindable || *makeRUnbindable
+	if isPropagation && len(flag.Args()) == 1 {
+		// A propagation change applies to an already-mounted path; it
+		// takes no device, unlike every other form of mount.
+		path := flag.Args()[0]
+		var err error
+		switch {
+		case *makeRShared:
+			err = mount.EnsureMountedAs(path, mount.MakeRShared)
+		case *makeShared:
+			err = mount.EnsureMountedAs(path, mount.MakeShared)
+		case *makeRSlave:
+			err = mount.EnsureMountedAs(path, mount.MakeRSlave)
+		case *makeSlave:
+			err = mount.EnsureMountedAs(path, mount.MakeSlave)
+		case *makeRPrivate:
+			err = mount.EnsureMountedAs(path, mount.MakeRPrivate)
+		case *makePrivate:
+			err = mount.EnsureMountedAs(path, mount.MakePrivate)
+		case *makeRUnbindable:
+			err = mount.EnsureMountedAs(path, mount.MakeRUnbindable)
+		case *makeUnbindable:
+			err = mount.EnsureMountedAs(path, mount.MakeUnbindable)
+		}
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		afterMount(execCmd)
+		return
+	}
+
+	if *move {
+		if len(flag.Args()) != 2 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		a := flag.Args()
+		if err := mount.Move(a[0], a[1]); err != nil {
+			log.Fatalf("%v", err)
+		}
+		afterMount(execCmd)
+		return
+	}
+
+	if *remount {
+		if len(flag.Args()) != 1 {
+			flag.Usage()
+			os.Exit(1)
 		}
+		path := flag.Args()[0]
+		var flags uintptr
+		var data []string
+		for _, option := range options {
+			if f, ok := opts[option]; ok {
+				flags |= f
+			} else {
+				data = append(data, option)
+			}
+		}
+		if *ro {
+			flags |= unix.MS_RDONLY
+		}
+		if *makeShared || *makeRShared {
+			flags |= unix.MS_SHARED
+		}
+		if *makeSlave || *makeRSlave {
+			flags |= unix.MS_SLAVE
+		}
+		if *makePrivate || *makeRPrivate {
+			flags |= unix.MS_PRIVATE
+		}
+		if *makeUnbindable || *makeRUnbindable {
+			flags |= unix.MS_UNBINDABLE
+		}
+		if *makeRShared || *makeRSlave || *makeRPrivate || *makeRUnbindable {
+			flags |= unix.MS_REC
+		}
+		if err := mount.Remount(path, strings.Join(data, ","), flags); err != nil {
+			log.Fatalf("%v", err)
+		}
+		afterMount(execCmd)
+		return
 	}
 
-	flag.Parse()
 	if len(flag.Args()) < 2 {
 		flag.Usage()
 		os.Exit(1)
@@ -177,4 +521,5 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	afterMount(execCmd)
 }
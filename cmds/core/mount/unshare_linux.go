@@ -0,0 +1,103 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/u-root/u-root/pkg/mount"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	unshare     = flag.Bool("N", false, "Perform the mount in a new, private mount namespace (same as --unshare)")
+	unshareLong = flag.Bool("unshare", false, "Perform the mount in a new, private mount namespace (same as -N)")
+	keepAlive   = flag.Bool("keep-alive", false, "With -N, block after mounting instead of exiting so another process can nsenter the namespace")
+)
+
+// unshareChildEnv marks the re-exec'd child of -N/--unshare. It has to be an
+// environment variable rather than a flag: the sentinel would otherwise have
+// to be ordered before the mount's own positional arguments (DEV PATH, OLD
+// NEW, PATH, ...) for flag.Parse to see it at all, since flag.Parse stops
+// looking for flags at the first non-flag argument.
+const unshareChildEnv = "UROOT_MOUNT_UNSHARE_CHILD"
+
+func isUnshareChild() bool {
+	return os.Getenv(unshareChildEnv) == "1"
+}
+
+// splitExec pulls a trailing "--exec CMD [ARGS...]" off the end of args,
+// since flag can't capture a variadic tail itself. It returns the
+// remaining flag/positional arguments and the exec command, if any.
+func splitExec(args []string) (rest, execCmd []string) {
+	for i, a := range args {
+		if a == "--exec" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// unshareAndReexec re-execs the current binary in a new mount namespace,
+// marked via unshareChildEnv, and waits for it. The re-exec is necessary
+// because Go schedules goroutines across OS threads, so a
+// unix.Unshare(CLONE_NEWNS) done by the already-running, multi-threaded
+// parent would not reliably apply to the thread that goes on to mount; the
+// re-exec'd child instead locks its main goroutine to one OS thread and
+// unshares that thread in main (see enterPrivateNamespace) before it mounts
+// anything.
+func unshareAndReexec(rest, execCmd []string) {
+	args := append([]string{}, rest...)
+	if len(execCmd) > 0 {
+		args = append(args, "--exec")
+		args = append(args, execCmd...)
+	}
+	cmd := exec.Command("/proc/self/exe", args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), unshareChildEnv+"=1")
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("%v", err)
+	}
+}
+
+// enterPrivateNamespace unshares the mount namespace of the calling,
+// just-started process and makes "/" recursively private so that mounts
+// performed from here on do not propagate back to the host.
+func enterPrivateNamespace() {
+	runtime.LockOSThread()
+	if err := unix.Unshare(unix.CLONE_NEWNS); err != nil {
+		log.Fatalf("failed to unshare mount namespace: %v", err)
+	}
+	if err := mount.MakeRPrivate("/"); err != nil {
+		log.Fatalf("%v", err)
+	}
+}
+
+// afterMount runs once the requested mount has been performed: it either
+// execs into the user-supplied command (replacing this process, same as a
+// shell exec, so it inherits the mount namespace) or, with --keep-alive,
+// blocks forever so an external process can nsenter(1) into this namespace.
+func afterMount(execCmd []string) {
+	if len(execCmd) > 0 {
+		bin, err := exec.LookPath(execCmd[0])
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := unix.Exec(bin, execCmd, os.Environ()); err != nil {
+			log.Fatalf("exec %s: %v", execCmd[0], err)
+		}
+		return
+	}
+	if *keepAlive {
+		select {}
+	}
+}
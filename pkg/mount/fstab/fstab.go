@@ -0,0 +1,117 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fstab parses fstab(5)-style mount tables, e.g. /etc/fstab.
+package fstab
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Entry is a single fstab entry, e.g. one non-comment line of /etc/fstab.
+type Entry struct {
+	// Spec is the block special device or remote filesystem to be mounted,
+	// e.g. "/dev/sda1", "UUID=...", or "host:/path" (fs_spec in fstab(5)).
+	Spec string
+
+	// File is the mount point (fs_file in fstab(5)).
+	File string
+
+	// VfsType is the filesystem type, or "auto" to let the kernel probe it
+	// (fs_vfstype in fstab(5)).
+	VfsType string
+
+	// MntOps is the comma-separated list of mount options (fs_mntops in
+	// fstab(5)).
+	MntOps string
+
+	// Freq is the dump(8) frequency, defaulting to 0 (fs_freq in fstab(5)).
+	Freq int
+
+	// PassNo is the fsck(8) pass number, defaulting to 0 (fs_passno in
+	// fstab(5)).
+	PassNo int
+}
+
+// Options splits MntOps into its comma-separated fields.
+func (e Entry) Options() []string {
+	if e.MntOps == "" {
+		return nil
+	}
+	return strings.Split(e.MntOps, ",")
+}
+
+// HasOption reports whether opt is one of the comma-separated fields of
+// MntOps.
+func (e Entry) HasOption(opt string) bool {
+	for _, o := range e.Options() {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads fstab-format entries from r. Blank lines and lines beginning
+// with "#" (after leading whitespace) are ignored, as are trailing comments.
+func Parse(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	s := bufio.NewScanner(r)
+	for lineNo := 1; s.Scan(); lineNo++ {
+		line := s.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("fstab:%d: too few fields in %q", lineNo, line)
+		}
+		e := Entry{
+			Spec:    fields[0],
+			File:    fields[1],
+			VfsType: fields[2],
+			MntOps:  "defaults",
+		}
+		if len(fields) > 3 {
+			e.MntOps = fields[3]
+		}
+		if len(fields) > 4 {
+			freq, err := strconv.Atoi(fields[4])
+			if err != nil {
+				return nil, fmt.Errorf("fstab:%d: invalid freq %q: %w", lineNo, fields[4], err)
+			}
+			e.Freq = freq
+		}
+		if len(fields) > 5 {
+			passNo, err := strconv.Atoi(fields[5])
+			if err != nil {
+				return nil, fmt.Errorf("fstab:%d: invalid pass number %q: %w", lineNo, fields[5], err)
+			}
+			e.PassNo = passNo
+		}
+		entries = append(entries, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ParseFile opens path and parses it as an fstab file.
+func ParseFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
@@ -0,0 +1,59 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fstab
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const data = `
+# a comment line, and a blank line above
+/dev/sda1  /          ext4   defaults        0  1
+/dev/sda2  /home      ext4   defaults,noatime 0  2 # trailing comment
+proc       /proc      proc   defaults
+tmpfs      /tmp       tmpfs  size=100%,nr_inodes=1M
+`
+	want := []Entry{
+		{Spec: "/dev/sda1", File: "/", VfsType: "ext4", MntOps: "defaults", Freq: 0, PassNo: 1},
+		{Spec: "/dev/sda2", File: "/home", VfsType: "ext4", MntOps: "defaults,noatime", Freq: 0, PassNo: 2},
+		{Spec: "proc", File: "/proc", VfsType: "proc", MntOps: "defaults"},
+		{Spec: "tmpfs", File: "/tmp", VfsType: "tmpfs", MntOps: "size=100%,nr_inodes=1M"},
+	}
+
+	got, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(%q) = %#v, want %#v", data, got, want)
+	}
+}
+
+func TestParseTooFewFields(t *testing.T) {
+	if _, err := Parse(strings.NewReader("/dev/sda1 /\n")); err == nil {
+		t.Error("Parse with only 2 fields: got nil error, want an error")
+	}
+}
+
+func TestOptionsAndHasOption(t *testing.T) {
+	e := Entry{MntOps: "noauto,ro,loop"}
+	want := []string{"noauto", "ro", "loop"}
+	if got := e.Options(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Options() = %v, want %v", got, want)
+	}
+	if !e.HasOption("loop") {
+		t.Error("HasOption(loop) = false, want true")
+	}
+	if e.HasOption("rw") {
+		t.Error("HasOption(rw) = true, want false")
+	}
+
+	if got := (Entry{}).Options(); got != nil {
+		t.Errorf("Options() on empty MntOps = %v, want nil", got)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mountinfo
+
+import (
+	"errors"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// Mounted reports whether path is itself a mount point (as opposed to a
+// plain directory inside one). It is meant for idempotency checks before
+// mounting, e.g. "is /var/lib/kubelet already a bind mount?".
+//
+// On kernels that support openat2(2) with RESOLVE_NO_XDEV (5.6+), this is a
+// single syscall that does not need to read or parse mountinfo at all. On
+// older kernels it falls back to a full parse of /proc/self/mountinfo.
+func Mounted(path string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, err
+	}
+	if mounted, ok, err := mountedFast(path); ok {
+		return mounted, err
+	}
+	return mountedSlow(path)
+}
+
+// mountedFast tries the openat2 RESOLVE_NO_XDEV trick: resolving the last
+// path component from its parent directory fails with EXDEV exactly when
+// that component is the root of a different mount, because RESOLVE_NO_XDEV
+// forbids crossing a mount boundary while resolving. ok is false when the
+// kernel does not support openat2, so the caller should fall back.
+func mountedFast(path string) (mounted bool, ok bool, err error) {
+	dir, last := filepath.Split(filepath.Clean(path))
+	if last == "" {
+		// path is "/".
+		return false, false, nil
+	}
+
+	parent, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY, 0)
+	if err != nil {
+		return false, true, err
+	}
+	defer unix.Close(parent)
+
+	how := unix.OpenHow{
+		Flags:   unix.O_PATH,
+		Resolve: unix.RESOLVE_NO_XDEV,
+	}
+	fd, err := unix.Openat2(parent, last, &how)
+	switch {
+	case err == nil:
+		unix.Close(fd)
+		return false, true, nil
+	case errors.Is(err, unix.EXDEV):
+		return true, true, nil
+	case errors.Is(err, unix.ENOSYS):
+		return false, false, nil
+	default:
+		return false, true, err
+	}
+}
+
+// mountedSlow parses the full mount table and looks for an entry whose
+// mount point is exactly path.
+func mountedSlow(path string) (bool, error) {
+	mounts, err := GetMounts()
+	if err != nil {
+		return false, err
+	}
+	for _, m := range mounts {
+		if m.Mountpoint == path {
+			return true, nil
+		}
+	}
+	return false, nil
+}
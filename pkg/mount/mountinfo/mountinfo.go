@@ -0,0 +1,157 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mountinfo parses /proc/[pid]/mountinfo, documented in
+// proc(5), into structured records.
+package mountinfo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MountInfo is a single parsed line of /proc/[pid]/mountinfo.
+type MountInfo struct {
+	// ID is the unique identifier of the mount (may be reused after umount).
+	ID int
+
+	// Parent is the ID of the parent mount, or ID of self for the root
+	// of the mount tree.
+	Parent int
+
+	// Major and Minor are the value of st_dev for files on this
+	// filesystem.
+	Major, Minor int
+
+	// Root is the pathname of the directory in the filesystem that forms
+	// the root of this mount.
+	Root string
+
+	// Mountpoint is the pathname of the mount point relative to the
+	// process's root.
+	Mountpoint string
+
+	// Options are the per-mount options.
+	Options []string
+
+	// Optional are the optional fields, e.g. "shared:2" or "master:3".
+	Optional []string
+
+	// FSType is the filesystem type, e.g. "ext4".
+	FSType string
+
+	// Source is the filesystem-specific information, e.g. the mounted
+	// device.
+	Source string
+
+	// VFSOptions are the per-superblock options.
+	VFSOptions []string
+}
+
+// unescape decodes the octal escapes (\040, \011, \012, \134 and \042) used
+// by the kernel for space, tab, newline, backslash, and double quote in
+// /proc/self/mountinfo fields. The double-quote case matters for CIFS/NFS
+// sources that legitimately contain one.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Parse parses mountinfo-formatted data, as found in
+// /proc/[pid]/mountinfo.
+func Parse(r io.Reader) ([]MountInfo, error) {
+	var infos []MountInfo
+	s := bufio.NewScanner(r)
+	for lineNo := 1; s.Scan(); lineNo++ {
+		mi, err := parseLine(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("mountinfo:%d: %w", lineNo, err)
+		}
+		infos = append(infos, mi)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+func parseLine(line string) (MountInfo, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return MountInfo{}, fmt.Errorf("too few fields in %q", line)
+	}
+
+	var mi MountInfo
+	var err error
+	if mi.ID, err = strconv.Atoi(fields[0]); err != nil {
+		return MountInfo{}, fmt.Errorf("invalid mount ID %q: %w", fields[0], err)
+	}
+	if mi.Parent, err = strconv.Atoi(fields[1]); err != nil {
+		return MountInfo{}, fmt.Errorf("invalid parent ID %q: %w", fields[1], err)
+	}
+	mm := strings.SplitN(fields[2], ":", 2)
+	if len(mm) != 2 {
+		return MountInfo{}, fmt.Errorf("invalid major:minor %q", fields[2])
+	}
+	if mi.Major, err = strconv.Atoi(mm[0]); err != nil {
+		return MountInfo{}, fmt.Errorf("invalid major %q: %w", mm[0], err)
+	}
+	if mi.Minor, err = strconv.Atoi(mm[1]); err != nil {
+		return MountInfo{}, fmt.Errorf("invalid minor %q: %w", mm[1], err)
+	}
+	mi.Root = unescape(fields[3])
+	mi.Mountpoint = unescape(fields[4])
+	mi.Options = strings.Split(fields[5], ",")
+
+	// fields[6:] is zero or more optional fields followed by a literal
+	// "-" separator.
+	i := 6
+	for ; i < len(fields) && fields[i] != "-"; i++ {
+		mi.Optional = append(mi.Optional, fields[i])
+	}
+	if i >= len(fields) || fields[i] != "-" {
+		return MountInfo{}, fmt.Errorf("missing \"-\" separator in %q", line)
+	}
+	i++
+	if i+3 > len(fields) {
+		return MountInfo{}, fmt.Errorf("too few fields after separator in %q", line)
+	}
+	mi.FSType = unescape(fields[i])
+	mi.Source = unescape(fields[i+1])
+	mi.VFSOptions = strings.Split(fields[i+2], ",")
+
+	return mi, nil
+}
+
+// ParseFile opens path and parses it as mountinfo data.
+func ParseFile(path string) ([]MountInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// GetMounts parses /proc/self/mountinfo.
+func GetMounts() ([]MountInfo, error) {
+	return ParseFile("/proc/self/mountinfo")
+}
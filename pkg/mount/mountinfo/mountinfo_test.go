@@ -0,0 +1,89 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mountinfo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	// Lines adapted from the format documented in proc(5), plus one with
+	// octal-escaped space and double-quote characters in the mount point
+	// (the double-quote case is the one moby/sys historically got wrong).
+	const data = `36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+60 35 0:34 / / rw,noatime - tmpfs tmpfs rw
+645 39 0:35 / /tmp/with\040space\042quote rw,noatime shared:268 - tmpfs tmpfs rw
+`
+	want := []MountInfo{
+		{
+			ID: 36, Parent: 35, Major: 98, Minor: 0,
+			Root: "/mnt1", Mountpoint: "/mnt2",
+			Options:    []string{"rw", "noatime"},
+			Optional:   []string{"master:1"},
+			FSType:     "ext3",
+			Source:     "/dev/root",
+			VFSOptions: []string{"rw", "errors=continue"},
+		},
+		{
+			ID: 60, Parent: 35, Major: 0, Minor: 34,
+			Root: "/", Mountpoint: "/",
+			Options:    []string{"rw", "noatime"},
+			Optional:   nil,
+			FSType:     "tmpfs",
+			Source:     "tmpfs",
+			VFSOptions: []string{"rw"},
+		},
+		{
+			ID: 645, Parent: 39, Major: 0, Minor: 35,
+			Root: "/", Mountpoint: `/tmp/with space"quote`,
+			Options:    []string{"rw", "noatime"},
+			Optional:   []string{"shared:268"},
+			FSType:     "tmpfs",
+			Source:     "tmpfs",
+			VFSOptions: []string{"rw"},
+		},
+	}
+
+	got, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(%q) = %#v, want %#v", data, got, want)
+	}
+}
+
+func TestParseMissingSeparator(t *testing.T) {
+	const data = `36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 ext3 /dev/root rw,errors=continue` + "\n"
+	if _, err := Parse(strings.NewReader(data)); err == nil {
+		t.Error("Parse with no \"-\" separator: got nil error, want an error")
+	}
+}
+
+func TestParseTooFewFields(t *testing.T) {
+	if _, err := Parse(strings.NewReader("36 35 98:0 /mnt1 /mnt2\n")); err == nil {
+		t.Error("Parse with too few fields: got nil error, want an error")
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"/mnt", "/mnt"},
+		{`/mnt/with\040space`, "/mnt/with space"},
+		{`/mnt/with\011tab`, "/mnt/with\ttab"},
+		{`/mnt/with\012newline`, "/mnt/with\nnewline"},
+		{`/mnt/with\134backslash`, `/mnt/with\backslash`},
+		{`/mnt/with\042quote`, `/mnt/with"quote`},
+	} {
+		if got := unescape(tt.in); got != tt.want {
+			t.Errorf("unescape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mount
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// Move moves an existing mount from src to dst, e.g. to relocate
+// /oldroot/proc to /proc after a switch_root.
+func Move(src, dst string) error {
+	if err := unix.Mount(src, dst, "", unix.MS_MOVE, ""); err != nil {
+		return fmt.Errorf("failed to move mount from %q to %q: %w", src, dst, err)
+	}
+	return nil
+}
+
+// Remount changes the options of the existing mount at path, e.g. to
+// promote a live root to read-only with Remount("/", "ro", unix.MS_RDONLY).
+func Remount(path, data string, flags uintptr) error {
+	if err := unix.Mount("", path, "", flags|unix.MS_REMOUNT, data); err != nil {
+		return fmt.Errorf("failed to remount %q: %w", path, err)
+	}
+	return nil
+}
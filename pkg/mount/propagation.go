@@ -0,0 +1,84 @@
+// Copyright 2012-2017 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mount
+
+import (
+	"fmt"
+
+	"github.com/u-root/u-root/pkg/mount/mountinfo"
+	"golang.org/x/sys/unix"
+)
+
+// changePropagation changes the propagation type of the already-mounted
+// path to flags, one of unix.MS_SHARED, unix.MS_SLAVE, unix.MS_PRIVATE or
+// unix.MS_UNBINDABLE, optionally combined with unix.MS_REC. Unlike a normal
+// mount, the kernel ignores source, fstype and data for this call, so they
+// are left empty.
+func changePropagation(path string, flags uintptr) error {
+	if err := unix.Mount("", path, "", flags, ""); err != nil {
+		return fmt.Errorf("failed to change propagation of %q to %#x: %w", path, flags, err)
+	}
+	return nil
+}
+
+// MakeShared makes path a shared mount.
+func MakeShared(path string) error {
+	return changePropagation(path, unix.MS_SHARED)
+}
+
+// MakeRShared recursively makes path and every mount beneath it shared.
+func MakeRShared(path string) error {
+	return changePropagation(path, unix.MS_SHARED|unix.MS_REC)
+}
+
+// MakeSlave makes path a slave mount.
+func MakeSlave(path string) error {
+	return changePropagation(path, unix.MS_SLAVE)
+}
+
+// MakeRSlave recursively makes path and every mount beneath it a slave
+// mount.
+func MakeRSlave(path string) error {
+	return changePropagation(path, unix.MS_SLAVE|unix.MS_REC)
+}
+
+// MakePrivate makes path a private mount.
+func MakePrivate(path string) error {
+	return changePropagation(path, unix.MS_PRIVATE)
+}
+
+// MakeRPrivate recursively makes path and every mount beneath it private.
+func MakeRPrivate(path string) error {
+	return changePropagation(path, unix.MS_PRIVATE|unix.MS_REC)
+}
+
+// MakeUnbindable makes path an unbindable mount.
+func MakeUnbindable(path string) error {
+	return changePropagation(path, unix.MS_UNBINDABLE)
+}
+
+// MakeRUnbindable recursively makes path and every mount beneath it
+// unbindable.
+func MakeRUnbindable(path string) error {
+	return changePropagation(path, unix.MS_UNBINDABLE|unix.MS_REC)
+}
+
+// EnsureMountedAs bind-mounts path onto itself if it is not already a mount
+// point, then calls change to set its propagation. This is the pattern
+// kubelet and similar tools use to make an existing directory (e.g. /var/lib/kubelet)
+// shared before creating bind mounts beneath it, since propagation can only
+// be changed on a mount point, not an arbitrary directory.
+func EnsureMountedAs(path string, change func(string) error) error {
+	mounted, err := mountinfo.Mounted(path)
+	if err != nil {
+		return fmt.Errorf("failed to check if %q is mounted: %w", path, err)
+	}
+	if !mounted {
+		if err := unix.Mount(path, path, "", unix.MS_BIND, ""); err != nil {
+			return fmt.Errorf("failed to bind-mount %q onto itself: %w", path, err)
+		}
+	}
+	return change(path)
+}